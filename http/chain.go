@@ -0,0 +1,60 @@
+package http
+
+import "net/http"
+
+//Chain accumulates HTTPHandlerWrappers and composes them around a handler using
+//the same ordering convention as Wrap: the last wrapper added runs outermost,
+//closest to the caller, and the first added runs innermost, closest to the
+//handler. Then delegates straight to Wrap, so a wrapper list behaves identically
+//whether it is passed to Wrap(h, list...) or built up via Chain/Append/Use — there
+//is a single, shared composition order for this package, not two that silently
+//disagree
+type Chain struct {
+	wrappers []HTTPHandlerWrapper
+}
+
+//NewChain creates a Chain seeded with the provided wrappers
+func NewChain(wrappers ...HTTPHandlerWrapper) Chain {
+	return Chain{wrappers: append([]HTTPHandlerWrapper{}, wrappers...)}
+}
+
+//Append returns a new Chain with wrappers added after the existing ones, so they
+//run further outward, closer to the caller
+func (c Chain) Append(wrappers ...HTTPHandlerWrapper) Chain {
+	merged := append([]HTTPHandlerWrapper{}, c.wrappers...)
+	return Chain{wrappers: append(merged, wrappers...)}
+}
+
+//With is an alias for Append, matching the chi/negroni naming convention
+func (c Chain) With(wrappers ...HTTPHandlerWrapper) Chain {
+	return c.Append(wrappers...)
+}
+
+//Use mutates the Chain in place, appending wrappers so applications can build a
+//mux declaratively without reassigning the Chain on every call
+func (c *Chain) Use(wrappers ...HTTPHandlerWrapper) {
+	c.wrappers = append(c.wrappers, wrappers...)
+}
+
+//Then composes the Chain's wrappers around h via Wrap and returns a standard
+//http.HandlerFunc ready to register on a mux
+func (c Chain) Then(h HTTPHandlerFunc) http.HandlerFunc {
+	return Wrap(h, c.wrappers...)
+}
+
+//Group is a Chain scoped to a path prefix, letting a set of routes share middleware
+//when mounted onto a ServeMux sub-tree
+type Group struct {
+	Chain
+	Prefix string
+}
+
+//NewGroup creates a Group for prefix seeded with the provided wrappers
+func NewGroup(prefix string, wrappers ...HTTPHandlerWrapper) Group {
+	return Group{Chain: NewChain(wrappers...), Prefix: prefix}
+}
+
+//HandleFunc registers h on mux at Prefix+pattern, wrapped with the Group's Chain
+func (g Group) HandleFunc(mux *http.ServeMux, pattern string, h HTTPHandlerFunc) {
+	mux.HandleFunc(g.Prefix+pattern, g.Then(h))
+}
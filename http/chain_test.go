@@ -0,0 +1,72 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+//markerWrapper appends name to order when its handler runs, so tests can assert on
+//execution order without depending on Audit/Recover/Error/Log's real side effects
+func markerWrapper(name string, order *[]string) HTTPHandlerWrapper {
+	return func(next HTTPHandlerFunc) HTTPHandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) error {
+			*order = append(*order, name)
+			return next(w, r)
+		}
+	}
+}
+
+//TestChainMatchesWrapOrder demonstrates that Chain.Then and Wrap compose the same
+//wrapper list identically: the last wrapper in the list runs outermost. A mux
+//built with Audit, Recover, Error, Log (in that execution order) lists them as
+//Log, Error, Recover, Audit.
+func TestChainMatchesWrapOrder(t *testing.T) {
+	want := []string{"Audit", "Recover", "Error", "Log"}
+
+	handler := func(w http.ResponseWriter, r *http.Request) error {
+		return nil
+	}
+
+	var chainOrder []string
+	chain := NewChain(
+		markerWrapper("Log", &chainOrder),
+		markerWrapper("Error", &chainOrder),
+		markerWrapper("Recover", &chainOrder),
+		markerWrapper("Audit", &chainOrder),
+	)
+	chain.Then(handler).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	if !reflect.DeepEqual(chainOrder, want) {
+		t.Fatalf("Chain execution order = %v, want %v", chainOrder, want)
+	}
+
+	var wrapOrder []string
+	wrapped := Wrap(handler,
+		markerWrapper("Log", &wrapOrder),
+		markerWrapper("Error", &wrapOrder),
+		markerWrapper("Recover", &wrapOrder),
+		markerWrapper("Audit", &wrapOrder),
+	)
+	wrapped.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	if !reflect.DeepEqual(wrapOrder, want) {
+		t.Fatalf("Wrap execution order = %v, want %v", wrapOrder, want)
+	}
+}
+
+//TestChainAppendRunsOutward demonstrates Append/With/Use add wrappers that run
+//further outward, matching Wrap's last-argument-outermost convention.
+func TestChainAppendRunsOutward(t *testing.T) {
+	want := []string{"outer", "inner"}
+	var order []string
+
+	handler := func(w http.ResponseWriter, r *http.Request) error {
+		return nil
+	}
+
+	chain := NewChain(markerWrapper("inner", &order)).Append(markerWrapper("outer", &order))
+	chain.Then(handler).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	if !reflect.DeepEqual(order, want) {
+		t.Fatalf("execution order = %v, want %v", order, want)
+	}
+}
@@ -0,0 +1,296 @@
+package http
+
+import (
+	"compress/flate"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/andybalholm/brotli"
+	"github.com/rjansen/haki"
+)
+
+//DefaultCompressibleTypes is the allow-list Compress checks the response
+//Content-Type against when types is not provided
+var DefaultCompressibleTypes = []string{
+	"application/json",
+	"text/",
+	"application/xml",
+}
+
+//DefaultMinCompressSize is a minSize, in bytes, to pass to Compress: bodies below
+//it are left uncompressed, so the encoding framing overhead never outweighs the
+//savings on tiny responses. Compress does not apply it on its own
+const DefaultMinCompressSize = 1024
+
+type compressEncoding string
+
+const (
+	encodingGzip    compressEncoding = "gzip"
+	encodingDeflate compressEncoding = "deflate"
+	encodingBrotli  compressEncoding = "br"
+)
+
+//compressWriter is the common surface gzip.Writer, flate.Writer and brotli.Writer
+//all satisfy, letting Compress pool them behind a single interface
+type compressWriter interface {
+	io.WriteCloser
+	Flush() error
+	Reset(io.Writer)
+}
+
+var encoderPools sync.Map
+
+//encoderPool returns the *sync.Pool caching encoders for encoding+level, creating
+//it on first use so Compress avoids allocating a new encoder per request
+func encoderPool(encoding compressEncoding, level int) *sync.Pool {
+	key := string(encoding) + ":" + strconv.Itoa(level)
+	if pool, ok := encoderPools.Load(key); ok {
+		return pool.(*sync.Pool)
+	}
+	pool := &sync.Pool{
+		New: func() interface{} {
+			return newEncoder(encoding, level)
+		},
+	}
+	actual, _ := encoderPools.LoadOrStore(key, pool)
+	return actual.(*sync.Pool)
+}
+
+func newEncoder(encoding compressEncoding, level int) compressWriter {
+	switch encoding {
+	case encodingGzip:
+		w, _ := gzip.NewWriterLevel(io.Discard, level)
+		return w
+	case encodingDeflate:
+		w, _ := flate.NewWriter(io.Discard, level)
+		return w
+	case encodingBrotli:
+		return brotli.NewWriterLevel(io.Discard, level)
+	default:
+		return nil
+	}
+}
+
+//Compress wraps the provided HTTPHandlerFunc, transparently encoding the response
+//body with gzip, deflate or brotli according to the request's Accept-Encoding. Only
+//responses whose Content-Type is in types (default DefaultCompressibleTypes) and
+//whose body reaches minSize bytes (use DefaultMinCompressSize for the common case)
+//are encoded; everything else, including content-types that are already
+//compressed, passes through untouched
+func Compress(level int, minSize int, types ...string) HTTPHandlerWrapper {
+	if len(types) == 0 {
+		types = DefaultCompressibleTypes
+	}
+	return func(handler HTTPHandlerFunc) HTTPHandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) error {
+			w.Header().Add("Vary", "Accept-Encoding")
+			encoding := bestEncoding(r.Header.Get("Accept-Encoding"))
+			if encoding == "" {
+				return handler(w, r)
+			}
+			cw := &compressResponseWriter{
+				ResponseWriter: asResponseWriter(w),
+				pool:           encoderPool(encoding, level),
+				encoding:       encoding,
+				allowTypes:     types,
+				minSize:        minSize,
+			}
+			err := handler(cw, r)
+			if closeErr := cw.Close(); err == nil {
+				err = closeErr
+			}
+			return err
+		}
+	}
+}
+
+//asResponseWriter reuses w if it already is a ResponseWriter, so byte accounting
+//composes with an outer Audit/Log wrapper instead of tracking sizes twice
+func asResponseWriter(w http.ResponseWriter) ResponseWriter {
+	if rw, ok := w.(ResponseWriter); ok {
+		return rw
+	}
+	return NewResponseWriter(w)
+}
+
+//compressResponseWriter buffers the first minSize bytes of a
+//response to decide whether it is worth compressing, then streams the rest
+//through a pooled compressWriter
+type compressResponseWriter struct {
+	ResponseWriter
+	pool         *sync.Pool
+	encoding     compressEncoding
+	allowTypes   []string
+	minSize      int
+	encoder      compressWriter
+	buffer       []byte
+	status       int
+	headerSet    bool
+	decided      bool
+	shouldEncode bool
+}
+
+//WriteHeader buffers the status instead of forwarding it immediately: the
+//compression decision (and the Content-Encoding/Content-Length headers it
+//implies) must be made before any header reaches the wire, otherwise a client
+//ends up with a committed, uncompressed response header followed by a
+//compressed body
+func (w *compressResponseWriter) WriteHeader(status int) {
+	w.status = status
+	w.headerSet = true
+}
+
+func (w *compressResponseWriter) Status() int {
+	if w.headerSet {
+		return w.status
+	}
+	return w.ResponseWriter.Status()
+}
+
+func (w *compressResponseWriter) Written() bool {
+	return w.headerSet || w.ResponseWriter.Written()
+}
+
+func (w *compressResponseWriter) Write(b []byte) (int, error) {
+	if !w.decided {
+		w.buffer = append(w.buffer, b...)
+		if len(w.buffer) < w.minSize {
+			return len(b), nil
+		}
+		if err := w.startEncoding(); err != nil {
+			return 0, err
+		}
+		return len(b), nil
+	}
+	if w.shouldEncode {
+		return w.encoder.Write(b)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+//startEncoding decides, based on the response Content-Type and the buffered size
+//so far, whether the buffered body should be compressed, commits the (possibly
+//amended) header exactly once, and then flushes the buffer through the chosen path
+func (w *compressResponseWriter) startEncoding() error {
+	w.decided = true
+	contentType := w.Header().Get(haki.ContentTypeHeader)
+	if len(w.buffer) >= w.minSize && compressibleType(contentType, w.allowTypes) && !alreadyCompressed(contentType) {
+		w.shouldEncode = true
+		w.Header().Set("Content-Encoding", string(w.encoding))
+		w.Header().Del("Content-Length")
+	}
+	if w.headerSet {
+		w.ResponseWriter.WriteHeader(w.status)
+	}
+	if w.shouldEncode {
+		w.encoder = w.pool.Get().(compressWriter)
+		w.encoder.Reset(w.ResponseWriter)
+		_, err := w.encoder.Write(w.buffer)
+		w.buffer = nil
+		return err
+	}
+	_, err := w.ResponseWriter.Write(w.buffer)
+	w.buffer = nil
+	return err
+}
+
+//Flush forces the compression decision on a body smaller than minSize, so
+//streaming/SSE handlers that never reach the threshold still see bytes flushed
+func (w *compressResponseWriter) Flush() {
+	if !w.decided {
+		w.startEncoding()
+	}
+	if w.shouldEncode {
+		w.encoder.Flush()
+	}
+	w.ResponseWriter.Flush()
+}
+
+//Close flushes any buffered body that never reached minSize and releases the
+//encoder back to its pool
+func (w *compressResponseWriter) Close() error {
+	if !w.decided {
+		if err := w.startEncoding(); err != nil {
+			return err
+		}
+	}
+	if w.shouldEncode {
+		err := w.encoder.Close()
+		w.pool.Put(w.encoder)
+		w.encoder = nil
+		return err
+	}
+	return nil
+}
+
+func compressibleType(contentType string, allowTypes []string) bool {
+	contentType = baseContentType(contentType)
+	if contentType == "" {
+		return false
+	}
+	for _, allowed := range allowTypes {
+		if strings.HasSuffix(allowed, "/") {
+			if strings.HasPrefix(contentType, allowed) {
+				return true
+			}
+			continue
+		}
+		if contentType == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+//alreadyCompressedTypePrefixes lists content-types Compress never re-encodes
+//because their payload is already compressed
+var alreadyCompressedTypePrefixes = []string{
+	"image/",
+	"video/",
+	"audio/",
+	"application/zip",
+	"application/gzip",
+	"application/x-gzip",
+}
+
+func alreadyCompressed(contentType string) bool {
+	contentType = baseContentType(contentType)
+	for _, prefix := range alreadyCompressedTypePrefixes {
+		if strings.HasSuffix(prefix, "/") && strings.HasPrefix(contentType, prefix) {
+			return true
+		}
+		if contentType == prefix {
+			return true
+		}
+	}
+	return false
+}
+
+//bestEncoding picks the highest-priority encoding this package supports out of the
+//request's Accept-Encoding header, returning "" when none is acceptable
+func bestEncoding(header string) compressEncoding {
+	if header == "" {
+		return ""
+	}
+	supported := map[string]compressEncoding{
+		"gzip":    encodingGzip,
+		"deflate": encodingDeflate,
+		"br":      encodingBrotli,
+	}
+	for _, entry := range parseAccept(header) {
+		if entry.quality <= 0 {
+			continue
+		}
+		if entry.mediaType == "*" {
+			return encodingGzip
+		}
+		if encoding, ok := supported[entry.mediaType]; ok {
+			return encoding
+		}
+	}
+	return ""
+}
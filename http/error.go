@@ -0,0 +1,99 @@
+package http
+
+import (
+	"github.com/rjansen/haki"
+	// registers text/plain so WriteByAccept can answer clients that ask for
+	// text/plain instead of DefaultContentType (json)
+	_ "github.com/rjansen/haki/media/text"
+	"net/http"
+	"strings"
+)
+
+//MethodNotAllowedError indicates the request's method has no route registered for it,
+//as matched by the enclosing mux or handler
+type MethodNotAllowedError struct {
+	Method string
+	Allow  []string
+}
+
+func (e MethodNotAllowedError) Error() string {
+	return "http: method not allowed: " + e.Method
+}
+
+//BadRequestError indicates the request could not be parsed or failed validation
+type BadRequestError struct {
+	Reason string
+}
+
+func (e BadRequestError) Error() string {
+	return e.Reason
+}
+
+//NotFoundError indicates the requested resource does not exist
+type NotFoundError struct {
+	Reason string
+}
+
+func (e NotFoundError) Error() string {
+	return e.Reason
+}
+
+//ForbiddenError indicates the caller is not allowed to perform the request
+type ForbiddenError struct {
+}
+
+func (e ForbiddenError) Error() string {
+	return "http: forbidden"
+}
+
+//CodeWithPayloadError carries an explicit status code, content-type and payload so a
+//handler can render a response that does not fit the other typed errors
+type CodeWithPayloadError struct {
+	Reason      string
+	StatusCode  int
+	ContentType string
+}
+
+func (e CodeWithPayloadError) Error() string {
+	return e.Reason
+}
+
+//errorPayload is the body written for typed errors through WriteByAccept: JSON
+//clients get its struct encoding ({"error": "..."}), while text/plain clients get
+//just the reason via String(), which encoding/json ignores
+type errorPayload struct {
+	Error string `json:"error"`
+}
+
+func (e errorPayload) String() string {
+	return e.Error
+}
+
+//renderError maps err to a status code and writes it to w via WriteByAccept, so
+//JSON clients get a structured {"error": "..."} payload, text/plain clients get
+//just the reason, and unrecognized Accept headers fall back to DefaultContentType
+func renderError(w http.ResponseWriter, r *http.Request, err error) {
+	status := http.StatusInternalServerError
+	reason := err.Error()
+	switch e := err.(type) {
+	case MethodNotAllowedError:
+		status = http.StatusMethodNotAllowed
+		if len(e.Allow) > 0 {
+			w.Header().Set("Allow", strings.Join(e.Allow, ", "))
+		}
+	case BadRequestError:
+		status = http.StatusBadRequest
+	case NotFoundError:
+		status = http.StatusNotFound
+	case ForbiddenError:
+		status = http.StatusForbidden
+	case CodeWithPayloadError:
+		w.Header().Set(haki.ContentTypeHeader, e.ContentType)
+		w.WriteHeader(e.StatusCode)
+		w.Write([]byte(reason))
+		return
+	}
+	if writeErr := WriteByAccept(w, r, status, errorPayload{Error: reason}); writeErr != nil {
+		http.Error(w, reason, status)
+	}
+}
@@ -2,77 +2,42 @@ package http
 
 import (
 	"context"
+	"fmt"
 	"github.com/rjansen/haki"
+	"github.com/rjansen/haki/media"
 	"github.com/rjansen/haki/media/json"
 	"github.com/rjansen/l"
 	"github.com/satori/go.uuid"
 	"net/http"
+	"runtime/debug"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 )
 
-//ResponseWriter is a wrapper function to store status and body length of the request
-type ResponseWriter interface {
-	http.ResponseWriter
-	http.Flusher
-	// Status returns the status code of the response or 200 if the response has
-	// not been written (as this is the default response code in net/http)
-	Status() int
-	// Written returns whether or not the ResponseWriter has been written.
-	Written() bool
-	// Size returns the size of the response body.
-	Size() int
-}
-
-// NewResponseWriter creates a ResponseWriter that wraps an http.ResponseWriter
-func NewResponseWriter(w http.ResponseWriter) ResponseWriter {
-	return &responseWriter{
-		ResponseWriter: w,
-	}
-}
-
-type responseWriter struct {
-	http.ResponseWriter
-	status int
-	size   int
+//get reads a value previously stored on the request context by set
+func get(r *http.Request, key interface{}) interface{} {
+	return r.Context().Value(key)
 }
 
-func (w *responseWriter) WriteHeader(s int) {
-	w.status = s
-	w.ResponseWriter.WriteHeader(s)
-}
-
-func (w *responseWriter) Write(b []byte) (int, error) {
-	if !w.Written() {
-		// The status will be 200 if WriteHeader has not been called yet
-		w.WriteHeader(http.StatusOK)
+//GetRequestID returns the request's id (tid) as set by Audit, falling back to the
+//raw haki.RequestIDHeader when the handler is not wrapped with Audit, or "" if
+//neither the context nor the header carry one
+func GetRequestID(r *http.Request) string {
+	if tid, ok := get(r, ContextKeys.TID).(string); ok {
+		return tid
 	}
-	size, err := w.ResponseWriter.Write(b)
-	w.size += size
-	return size, err
-}
-
-func (w *responseWriter) Status() int {
-	return w.status
-}
-
-func (w *responseWriter) Size() int {
-	return w.size
+	return r.Header.Get(haki.RequestIDHeader)
 }
 
-func (w *responseWriter) Written() bool {
-	return w.status != 0
-}
-
-func (w *responseWriter) Flush() {
-	flusher, ok := w.ResponseWriter.(http.Flusher)
-	if ok {
-		if !w.Written() {
-			// The status will be 200 if WriteHeader has not been called yet
-			w.WriteHeader(http.StatusOK)
-		}
-		flusher.Flush()
+//GetCorrelationID returns the request's correlation id as set by Audit, falling
+//back to the raw haki.RequestContextHeader when the handler is not wrapped with Audit
+func GetCorrelationID(r *http.Request) string {
+	if cid, ok := get(r, ContextKeys.CID).(string); ok {
+		return cid
 	}
+	return r.Header.Get(haki.RequestContextHeader)
 }
 
 //SimpleHTTPHandler is a contract for fast http handlers
@@ -113,8 +78,7 @@ func Handler(handler HTTPHandlerFunc) http.HandlerFunc {
 
 func errorHandle(handler HTTPHandlerFunc, w http.ResponseWriter, r *http.Request) error {
 	if err := handler(w, r); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return err
+		return Err(w, r, err)
 	}
 	return nil
 }
@@ -135,7 +99,7 @@ func (h ErrorHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 func logHandle(handler HTTPHandlerFunc, w http.ResponseWriter, r *http.Request) error {
 	tid := uuid.NewV4().String()
 	r = r.WithContext(context.WithValue(r.Context(), "tid", tid))
-	logger := l.WithFields(
+	logger := loggerFactory(
 		l.String("tid", tid),
 		l.String("method", r.Method),
 		l.String("path", r.URL.Path),
@@ -177,15 +141,20 @@ func (h LogHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 func auditHandle(handler HTTPHandlerFunc, w http.ResponseWriter, r *http.Request) error {
 	start := time.Now()
-	tid := uuid.NewV4().String()
-
+	tid := r.Header.Get(haki.RequestIDHeader)
+	if tid == "" {
+		tid = uuid.NewV4().String()
+	}
 	cid := r.Header.Get(haki.RequestContextHeader)
+	if cid == "" {
+		cid = uuid.NewV4().String()
+	}
 
 	w.Header().Set(haki.RequestIDHeader, tid)
 	w.Header().Set(haki.RequestContextHeader, cid)
 
 	r = set(r, ContextKeys.TID, tid)
-	r = set(r, ContextKeys.CID, tid)
+	r = set(r, ContextKeys.CID, cid)
 
 	identity := &Identity{
 		Token: "tanonymous",
@@ -194,7 +163,7 @@ func auditHandle(handler HTTPHandlerFunc, w http.ResponseWriter, r *http.Request
 			"Name": "User Anonymous",
 		},
 	}
-	logger := l.WithFields(
+	logger := loggerFactory(
 		l.String("tid", tid),
 		l.String("cid", cid),
 		l.String("method", r.Method),
@@ -218,7 +187,7 @@ func auditHandle(handler HTTPHandlerFunc, w http.ResponseWriter, r *http.Request
 
 	rw := NewResponseWriter(w)
 	var err error
-	if err = handler(rw, r); err != nil {
+	if err = recoverHandle(handler, rw, r); err != nil {
 		auditor.Error("haki.http.RequestErr",
 			l.Err(err),
 		)
@@ -232,7 +201,8 @@ func auditHandle(handler HTTPHandlerFunc, w http.ResponseWriter, r *http.Request
 	return err
 }
 
-//Audit wraps the provided HTTPHandlerFunc with access logging, error and audit control
+//Audit wraps the provided HTTPHandlerFunc with access logging, error, audit and
+//panic recovery control
 func Audit(handler HTTPHandlerFunc) HTTPHandlerFunc {
 	return Error(
 		func(w http.ResponseWriter, r *http.Request) error {
@@ -247,30 +217,135 @@ func (h AuditHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	auditHandle(HTTPHandlerFunc(h), w, r)
 }
 
-//ReadByContentType reads data from context using the Content-Type header to define the media type
+func recoverHandle(handler HTTPHandlerFunc, w http.ResponseWriter, r *http.Request) (err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			stack := debug.Stack()
+			fields := []l.Field{
+				l.String("recover", fmt.Sprintf("%v", recovered)),
+				l.String("stack", string(stack)),
+			}
+			if auditor, ok := get(r, ContextKeys.AUDITOR).(*Auditor); ok {
+				auditor.Error("haki.http.Panic", fields...)
+			} else if logger, ok := get(r, ContextKeys.LOG).(l.Logger); ok {
+				logger.Error("haki.http.Panic", fields...)
+			} else {
+				l.Error("haki.http.Panic", fields...)
+			}
+			err = fmt.Errorf("haki.http.Panic: %v", recovered)
+		}
+	}()
+	return handler(w, r)
+}
+
+//Recover wraps the provided HTTPHandlerFunc with panic recovery control, routing
+//the stack trace through the request's Auditor/Logger and re-exposing the panic as
+//an error return. It does not render a response itself: compose it underneath an
+//Error wrapper (as Audit already does) so exactly one response gets written
+func Recover(handler HTTPHandlerFunc) HTTPHandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		return recoverHandle(handler, w, r)
+	}
+}
+
+type RecoverHandler func(http.ResponseWriter, *http.Request) error
+
+func (h RecoverHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	recoverHandle(HTTPHandlerFunc(h), w, r)
+}
+
+//DefaultContentType is the media type used by WriteByAccept when the Accept
+//header is absent, wildcard, or matches nothing in the registry
+var DefaultContentType = json.ContentType
+
+//ReadByContentType reads data from the request body using the Content-Type header
+//to resolve the Media registered for it
 func ReadByContentType(r *http.Request, data interface{}) error {
-	contentType := r.Header.Get(haki.ContentTypeHeader)
-	switch {
-	case strings.Contains(contentType, json.ContentType):
-		return ReadJSON(r, data)
-	// case strings.Contains(contentType, proto.ContentType):
-	// 	return ReadProtoBuff(r, data)
-	default:
+	contentType := baseContentType(r.Header.Get(haki.ContentTypeHeader))
+	m, ok := media.Lookup(contentType)
+	if !ok {
 		return haki.ErrInvalidContentType
 	}
+	return m.Unmarshal(r.Body, data)
 }
 
-//WriteByAccept writes data to context using the Accept header to define the media type
+//WriteByAccept writes data to the response using the Accept header to resolve the
+//best registered Media, falling back to DefaultContentType when nothing matches
 func WriteByAccept(w http.ResponseWriter, r *http.Request, status int, result interface{}) error {
-	contentType := r.Header.Get(haki.AcceptHeader)
-	switch {
-	case strings.Contains(contentType, json.ContentType):
-		return JSON(w, status, result)
-	// case bytes.Contains(contentType, []byte(proto.ContentType)):
-	// 	return ProtoBuff(ctx, status, result)
-	default:
+	contentType, m, ok := acceptedMedia(r.Header.Get(haki.AcceptHeader))
+	if !ok {
 		return haki.ErrInvalidAccept
 	}
+	w.Header().Set(haki.ContentTypeHeader, contentType)
+	w.WriteHeader(status)
+	return m.Marshal(w, result)
+}
+
+//baseContentType strips any parameters (e.g. "; charset=utf-8") from a Content-Type header
+func baseContentType(contentType string) string {
+	if idx := strings.IndexByte(contentType, ';'); idx >= 0 {
+		contentType = contentType[:idx]
+	}
+	return strings.TrimSpace(contentType)
+}
+
+//acceptEntry is a single media-range parsed out of an Accept header
+type acceptEntry struct {
+	mediaType string
+	quality   float64
+}
+
+//parseAccept parses an Accept header into its media-ranges, ordered from the
+//highest q-value to the lowest
+func parseAccept(header string) []acceptEntry {
+	ranges := strings.Split(header, ",")
+	entries := make([]acceptEntry, 0, len(ranges))
+	for _, mediaRange := range ranges {
+		params := strings.Split(mediaRange, ";")
+		mediaType := strings.TrimSpace(params[0])
+		if mediaType == "" {
+			continue
+		}
+		entry := acceptEntry{mediaType: mediaType, quality: 1.0}
+		for _, param := range params[1:] {
+			name, value, found := strings.Cut(strings.TrimSpace(param), "=")
+			if !found || name != "q" {
+				continue
+			}
+			if quality, err := strconv.ParseFloat(value, 64); err == nil {
+				entry.quality = quality
+			}
+		}
+		entries = append(entries, entry)
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].quality > entries[j].quality
+	})
+	return entries
+}
+
+//acceptedMedia resolves the best registered Media for the provided Accept header,
+//falling back to DefaultContentType when the header is empty or matches nothing
+//registered. A "*/*" entry is skipped rather than stopping the search, so a more
+//specific registered type listed later at the same quality still gets matched
+func acceptedMedia(header string) (string, media.Media, bool) {
+	if header == "" {
+		m, ok := media.Lookup(DefaultContentType)
+		return DefaultContentType, m, ok
+	}
+	for _, entry := range parseAccept(header) {
+		if entry.quality <= 0 {
+			continue
+		}
+		if entry.mediaType == "*/*" {
+			continue
+		}
+		if m, ok := media.Lookup(entry.mediaType); ok {
+			return entry.mediaType, m, true
+		}
+	}
+	m, ok := media.Lookup(DefaultContentType)
+	return DefaultContentType, m, ok
 }
 
 //ReadJSON unmarshals from provided context a json media into data
@@ -305,8 +380,14 @@ func Status(w http.ResponseWriter, status int) error {
 	return nil
 }
 
-func Err(w http.ResponseWriter, err error) error {
-	http.Error(w, err.Error(), http.StatusInternalServerError)
+//Err renders err as an HTTP response. Errors from this package's typed error
+//hierarchy (MethodNotAllowedError, BadRequestError, NotFoundError, ForbiddenError,
+//CodeWithPayloadError) are rendered with their matching status code; anything
+//else falls back to a 500. The body is written via WriteByAccept so JSON clients
+//get a structured {"error": "..."} payload, text/plain clients get the plain reason,
+//and unrecognized Accept headers fall back to DefaultContentType
+func Err(w http.ResponseWriter, r *http.Request, err error) error {
+	renderError(w, r, err)
 	return err
 }
 
@@ -321,6 +402,6 @@ func (h BaseHandler) Status(w http.ResponseWriter, status int) error {
 	return Status(w, status)
 }
 
-func (h BaseHandler) Err(w http.ResponseWriter, err error) error {
-	return Err(w, err)
+func (h BaseHandler) Err(w http.ResponseWriter, r *http.Request, err error) error {
+	return Err(w, r, err)
 }
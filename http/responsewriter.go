@@ -0,0 +1,205 @@
+package http
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+)
+
+//ResponseWriter is a wrapper function to store status and body length of the request
+type ResponseWriter interface {
+	http.ResponseWriter
+	http.Flusher
+	// Status returns the status code of the response or 0 if the response has
+	// not been written yet (Written reports this by checking for a non-zero status)
+	Status() int
+	// Written returns whether or not the ResponseWriter has been written.
+	Written() bool
+	// Size returns the size of the response body.
+	Size() int
+	// Flushed returns whether or not Flush has reached an underlying writer that
+	// implements http.Flusher, which streaming/SSE handlers use to tell whether
+	// headers are already committed.
+	Flushed() bool
+}
+
+// NewResponseWriter creates a ResponseWriter that wraps an http.ResponseWriter. The
+// concrete type returned also implements http.Hijacker, http.Pusher and
+// http.CloseNotifier whenever the underlying writer does, so WebSocket upgrades,
+// HTTP/2 server push and SSE handlers keep working through the wrapper, à la
+// negroni/chi.
+//
+// The switch below is a fixed type matrix, not a general interface composer: it has
+// one concrete type for each of the eight combinations of Hijacker, Pusher and
+// CloseNotifier, so none of those three is dropped. Optional interfaces other than
+// these three (and http.Flusher, which every ResponseWriter implements) are not
+// passed through.
+func NewResponseWriter(w http.ResponseWriter) ResponseWriter {
+	base := responseWriter{ResponseWriter: w}
+	_, isHijacker := w.(http.Hijacker)
+	_, isPusher := w.(http.Pusher)
+	_, isCloseNotifier := w.(http.CloseNotifier)
+
+	switch {
+	case isHijacker && isPusher && isCloseNotifier:
+		return &hijackPusherCloseNotifyWriter{base}
+	case isHijacker && isPusher:
+		return &hijackPusherWriter{base}
+	case isHijacker && isCloseNotifier:
+		return &hijackCloseNotifyWriter{base}
+	case isPusher && isCloseNotifier:
+		return &pusherCloseNotifyWriter{base}
+	case isHijacker:
+		return &hijackWriter{base}
+	case isPusher:
+		return &pusherWriter{base}
+	case isCloseNotifier:
+		return &closeNotifyWriter{base}
+	default:
+		return &base
+	}
+}
+
+type responseWriter struct {
+	http.ResponseWriter
+	status  int
+	size    int
+	flushed bool
+}
+
+func (w *responseWriter) WriteHeader(s int) {
+	w.status = s
+	w.ResponseWriter.WriteHeader(s)
+}
+
+func (w *responseWriter) Write(b []byte) (int, error) {
+	if !w.Written() {
+		// The status will be 200 if WriteHeader has not been called yet
+		w.WriteHeader(http.StatusOK)
+	}
+	size, err := w.ResponseWriter.Write(b)
+	w.size += size
+	return size, err
+}
+
+func (w *responseWriter) Status() int {
+	return w.status
+}
+
+func (w *responseWriter) Size() int {
+	return w.size
+}
+
+func (w *responseWriter) Written() bool {
+	return w.status != 0
+}
+
+func (w *responseWriter) Flushed() bool {
+	return w.flushed
+}
+
+func (w *responseWriter) Flush() {
+	flusher, ok := w.ResponseWriter.(http.Flusher)
+	if ok {
+		if !w.Written() {
+			// The status will be 200 if WriteHeader has not been called yet
+			w.WriteHeader(http.StatusOK)
+		}
+		w.flushed = true
+		flusher.Flush()
+	}
+}
+
+//hijackWriter is a responseWriter for an underlying http.ResponseWriter that also
+//implements http.Hijacker, e.g. plain HTTP/1.1 connections used for WebSocket upgrades
+type hijackWriter struct {
+	responseWriter
+}
+
+func (w *hijackWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return w.ResponseWriter.(http.Hijacker).Hijack()
+}
+
+//closeNotifyWriter is a responseWriter for an underlying http.ResponseWriter that
+//also implements http.CloseNotifier
+type closeNotifyWriter struct {
+	responseWriter
+}
+
+func (w *closeNotifyWriter) CloseNotify() <-chan bool {
+	return w.ResponseWriter.(http.CloseNotifier).CloseNotify()
+}
+
+//hijackCloseNotifyWriter is a responseWriter for an underlying http.ResponseWriter
+//that implements both http.Hijacker and http.CloseNotifier, the common shape for
+//HTTP/1.1 connections
+type hijackCloseNotifyWriter struct {
+	responseWriter
+}
+
+func (w *hijackCloseNotifyWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return w.ResponseWriter.(http.Hijacker).Hijack()
+}
+
+func (w *hijackCloseNotifyWriter) CloseNotify() <-chan bool {
+	return w.ResponseWriter.(http.CloseNotifier).CloseNotify()
+}
+
+//pusherWriter is a responseWriter for an underlying http.ResponseWriter that also
+//implements http.Pusher, e.g. HTTP/2 connections that support server push
+type pusherWriter struct {
+	responseWriter
+}
+
+func (w *pusherWriter) Push(target string, opts *http.PushOptions) error {
+	return w.ResponseWriter.(http.Pusher).Push(target, opts)
+}
+
+//pusherCloseNotifyWriter is a responseWriter for an underlying http.ResponseWriter
+//that implements both http.Pusher and http.CloseNotifier, the common shape for
+//HTTP/2 connections
+type pusherCloseNotifyWriter struct {
+	responseWriter
+}
+
+func (w *pusherCloseNotifyWriter) Push(target string, opts *http.PushOptions) error {
+	return w.ResponseWriter.(http.Pusher).Push(target, opts)
+}
+
+func (w *pusherCloseNotifyWriter) CloseNotify() <-chan bool {
+	return w.ResponseWriter.(http.CloseNotifier).CloseNotify()
+}
+
+//hijackPusherWriter is a responseWriter for an underlying http.ResponseWriter that
+//implements both http.Hijacker and http.Pusher. net/http never produces this shape
+//itself, but test doubles and third-party middleware writers sometimes do
+type hijackPusherWriter struct {
+	responseWriter
+}
+
+func (w *hijackPusherWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return w.ResponseWriter.(http.Hijacker).Hijack()
+}
+
+func (w *hijackPusherWriter) Push(target string, opts *http.PushOptions) error {
+	return w.ResponseWriter.(http.Pusher).Push(target, opts)
+}
+
+//hijackPusherCloseNotifyWriter is a responseWriter for an underlying
+//http.ResponseWriter that implements http.Hijacker, http.Pusher and
+//http.CloseNotifier all at once
+type hijackPusherCloseNotifyWriter struct {
+	responseWriter
+}
+
+func (w *hijackPusherCloseNotifyWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return w.ResponseWriter.(http.Hijacker).Hijack()
+}
+
+func (w *hijackPusherCloseNotifyWriter) Push(target string, opts *http.PushOptions) error {
+	return w.ResponseWriter.(http.Pusher).Push(target, opts)
+}
+
+func (w *hijackPusherCloseNotifyWriter) CloseNotify() <-chan bool {
+	return w.ResponseWriter.(http.CloseNotifier).CloseNotify()
+}
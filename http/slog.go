@@ -0,0 +1,66 @@
+package http
+
+import (
+	"log/slog"
+
+	"github.com/rjansen/l"
+)
+
+//SlogLogger adapts a *slog.Logger to the l.Logger interface so applications are not
+//locked to github.com/rjansen/l as the backing sink for Log and Audit. Register it
+//with SetLoggerFactory to make logHandle/auditHandle build their per-request
+//loggers through it instead of the default github.com/rjansen/l sink
+type SlogLogger struct {
+	Logger *slog.Logger
+}
+
+//NewSlogLogger wraps logger so it can be handed to l.WithFields-consuming code, or
+//stored directly via ContextKeys.LOG, as a drop-in replacement for l.Logger
+func NewSlogLogger(logger *slog.Logger) SlogLogger {
+	return SlogLogger{Logger: logger}
+}
+
+//LoggerFactory builds the per-request Logger that Log and Audit attach to the
+//request context, given the fields they always collect (tid, method, path, ...)
+type LoggerFactory func(fields ...l.Field) l.Logger
+
+//loggerFactory is the LoggerFactory logHandle/auditHandle consult; it defaults to
+//github.com/rjansen/l so existing applications see no behavior change
+var loggerFactory LoggerFactory = l.WithFields
+
+//SetLoggerFactory overrides the LoggerFactory Log and Audit use to build each
+//request's Logger, letting applications back them with SlogLogger (or anything
+//else satisfying l.Logger) instead of the default github.com/rjansen/l sink. For
+//example: http.SetLoggerFactory(func(fields ...l.Field) l.Logger {
+//	return http.NewSlogLogger(slog.Default()).WithFields(fields...)
+//})
+func SetLoggerFactory(factory LoggerFactory) {
+	loggerFactory = factory
+}
+
+//WithFields returns a SlogLogger whose Info/Error calls always include fields
+//ahead of whatever is passed at the call site, mirroring l.WithFields so
+//SlogLogger can back SetLoggerFactory directly
+func (s SlogLogger) WithFields(fields ...l.Field) l.Logger {
+	return SlogLogger{Logger: s.Logger.With(toSlogArgs(fields)...)}
+}
+
+//Info logs msg at info level, translating fields to slog key/value pairs
+func (s SlogLogger) Info(msg string, fields ...l.Field) {
+	s.Logger.Info(msg, toSlogArgs(fields)...)
+}
+
+//Error logs msg at error level, translating fields to slog key/value pairs
+func (s SlogLogger) Error(msg string, fields ...l.Field) {
+	s.Logger.Error(msg, toSlogArgs(fields)...)
+}
+
+//toSlogArgs flattens l.Field values into the alternating key/value arguments
+//slog.Logger expects
+func toSlogArgs(fields []l.Field) []interface{} {
+	args := make([]interface{}, 0, len(fields)*2)
+	for _, field := range fields {
+		args = append(args, field.Key, field.Value)
+	}
+	return args
+}
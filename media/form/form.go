@@ -0,0 +1,69 @@
+package form
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+
+	"github.com/ajg/form"
+	"github.com/rjansen/haki/media"
+)
+
+//ContentType is the media type identifier this package registers itself under
+const ContentType = "application/x-www-form-urlencoded"
+
+//Marshal writes a form-urlencoded representation of the struct instance
+func Marshal(w io.Writer, data interface{}) error {
+	return form.NewEncoder(w).Encode(&data)
+}
+
+//Unmarshal reads a form-urlencoded representation into the struct instance
+func Unmarshal(r io.Reader, result interface{}) error {
+	body, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	return form.NewDecoder(bytes.NewReader(body)).Decode(&result)
+}
+
+//MarshalBytes writes a form-urlencoded representation of the struct instance
+func MarshalBytes(data interface{}) ([]byte, error) {
+	var buffer bytes.Buffer
+	if err := Marshal(&buffer, data); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+//UnmarshalBytes reads a form-urlencoded representation into the struct instance
+func UnmarshalBytes(raw []byte, result interface{}) error {
+	return Unmarshal(bytes.NewReader(raw), result)
+}
+
+//Media is a struct to helps writes and reads of a form-urlencoded representation
+type Media struct {
+}
+
+//Marshal writes a form-urlencoded representation of the struct instance
+func (Media) Marshal(writer io.Writer, val interface{}) error {
+	return Marshal(writer, &val)
+}
+
+//Unmarshal reads a form-urlencoded representation into the struct instance
+func (Media) Unmarshal(reader io.Reader, ref interface{}) error {
+	return Unmarshal(reader, &ref)
+}
+
+//MarshalBytes writes a form-urlencoded representation of the struct instance
+func (Media) MarshalBytes(val interface{}) ([]byte, error) {
+	return MarshalBytes(&val)
+}
+
+//UnmarshalBytes reads a form-urlencoded representation into the struct instance
+func (Media) UnmarshalBytes(raw []byte, ref interface{}) error {
+	return UnmarshalBytes(raw, &ref)
+}
+
+func init() {
+	media.Register(ContentType, Media{})
+}
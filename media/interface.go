@@ -15,3 +15,19 @@ type Media interface {
 	//UnmarshalBytes reads a json representation into the struct instance
 	UnmarshalBytes([]byte, interface{}) error
 }
+
+var registry = make(map[string]Media)
+
+//Register associates a Media implementation with a content-type so it can later
+//be resolved by Lookup. Implementations are expected to call this from an init
+//func using their own ContentType constant
+func Register(contentType string, m Media) {
+	registry[contentType] = m
+}
+
+//Lookup returns the Media implementation registered for the given content-type
+//and whether one was found
+func Lookup(contentType string) (Media, bool) {
+	m, ok := registry[contentType]
+	return m, ok
+}
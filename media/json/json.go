@@ -4,8 +4,13 @@ import (
 	"encoding/json"
 	"farm.e-pedion.com/repo/logger"
 	"io"
+
+	"github.com/rjansen/haki/media"
 )
 
+//ContentType is the media type identifier this package registers itself under
+const ContentType = "application/json"
+
 //Marshal writes a json representation of the struct instance
 func Marshal(w io.Writer, data interface{}) error {
 	return json.NewEncoder(w).Encode(&data)
@@ -58,4 +63,8 @@ func (Media) MarshalBytes(val interface{}) ([]byte, error) {
 //UnmarshalBytes reads a json representation into the struct instance
 func (Media) UnmarshalBytes(raw []byte, ref interface{}) error {
 	return UnmarshalBytes(raw, &ref)
+}
+
+func init() {
+	media.Register(ContentType, Media{})
 }
\ No newline at end of file
@@ -0,0 +1,81 @@
+package proto
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/rjansen/haki/media"
+)
+
+//ContentType is the media type identifier this package registers itself under
+const ContentType = "application/x-protobuf"
+
+//ErrNotAProtoMessage is returned when the provided value does not implement proto.Message
+var ErrNotAProtoMessage = errors.New("proto: value does not implement proto.Message")
+
+//Marshal writes a protobuf representation of the struct instance
+func Marshal(w io.Writer, data interface{}) error {
+	bytes, err := MarshalBytes(data)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(bytes)
+	return err
+}
+
+//Unmarshal reads a protobuf representation into the struct instance
+func Unmarshal(r io.Reader, result interface{}) error {
+	bytes, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	return UnmarshalBytes(bytes, result)
+}
+
+//MarshalBytes writes a protobuf representation of the struct instance
+func MarshalBytes(data interface{}) ([]byte, error) {
+	msg, ok := data.(proto.Message)
+	if !ok {
+		return nil, ErrNotAProtoMessage
+	}
+	return proto.Marshal(msg)
+}
+
+//UnmarshalBytes reads a protobuf representation into the struct instance
+func UnmarshalBytes(raw []byte, result interface{}) error {
+	msg, ok := result.(proto.Message)
+	if !ok {
+		return ErrNotAProtoMessage
+	}
+	return proto.Unmarshal(raw, msg)
+}
+
+//Media is a struct to helps writes and reads of a protobuf representation
+type Media struct {
+}
+
+//Marshal writes a protobuf representation of the struct instance
+func (Media) Marshal(writer io.Writer, val interface{}) error {
+	return Marshal(writer, val)
+}
+
+//Unmarshal reads a protobuf representation into the struct instance
+func (Media) Unmarshal(reader io.Reader, ref interface{}) error {
+	return Unmarshal(reader, ref)
+}
+
+//MarshalBytes writes a protobuf representation of the struct instance
+func (Media) MarshalBytes(val interface{}) ([]byte, error) {
+	return MarshalBytes(val)
+}
+
+//UnmarshalBytes reads a protobuf representation into the struct instance
+func (Media) UnmarshalBytes(raw []byte, ref interface{}) error {
+	return UnmarshalBytes(raw, ref)
+}
+
+func init() {
+	media.Register(ContentType, Media{})
+}
@@ -0,0 +1,96 @@
+package text
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	"github.com/rjansen/haki/media"
+)
+
+//ContentType is the media type identifier this package registers itself under
+const ContentType = "text/plain"
+
+//ErrNotAStringPointer is returned by Unmarshal/UnmarshalBytes when the destination
+//is not a *string
+var ErrNotAStringPointer = errors.New("text: destination is not a *string")
+
+//Marshal writes a plain-text representation of data: its error message or
+//String() when available, falling back to fmt's default formatting otherwise
+func Marshal(w io.Writer, data interface{}) error {
+	_, err := io.WriteString(w, stringify(data))
+	return err
+}
+
+//Unmarshal reads the entire body as plain text into result, which must be a *string
+func Unmarshal(r io.Reader, result interface{}) error {
+	body, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	ref, ok := result.(*string)
+	if !ok {
+		return ErrNotAStringPointer
+	}
+	*ref = string(body)
+	return nil
+}
+
+//MarshalBytes writes a plain-text representation of data
+func MarshalBytes(data interface{}) ([]byte, error) {
+	return []byte(stringify(data)), nil
+}
+
+//UnmarshalBytes reads a plain-text representation into result, which must be a *string
+func UnmarshalBytes(raw []byte, result interface{}) error {
+	ref, ok := result.(*string)
+	if !ok {
+		return ErrNotAStringPointer
+	}
+	*ref = string(raw)
+	return nil
+}
+
+func stringify(data interface{}) string {
+	switch v := data.(type) {
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	case error:
+		return v.Error()
+	case fmt.Stringer:
+		return v.String()
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+//Media is a struct to helps writes and reads of a plain-text representation
+type Media struct {
+}
+
+//Marshal writes a plain-text representation of the struct instance
+func (Media) Marshal(writer io.Writer, val interface{}) error {
+	return Marshal(writer, val)
+}
+
+//Unmarshal reads a plain-text representation into the struct instance
+func (Media) Unmarshal(reader io.Reader, ref interface{}) error {
+	return Unmarshal(reader, ref)
+}
+
+//MarshalBytes writes a plain-text representation of the struct instance
+func (Media) MarshalBytes(val interface{}) ([]byte, error) {
+	return MarshalBytes(val)
+}
+
+//UnmarshalBytes reads a plain-text representation into the struct instance
+func (Media) UnmarshalBytes(raw []byte, ref interface{}) error {
+	return UnmarshalBytes(raw, ref)
+}
+
+func init() {
+	media.Register(ContentType, Media{})
+}
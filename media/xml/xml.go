@@ -0,0 +1,59 @@
+package xml
+
+import (
+	"encoding/xml"
+	"io"
+
+	"github.com/rjansen/haki/media"
+)
+
+//ContentType is the media type identifier this package registers itself under
+const ContentType = "application/xml"
+
+//Marshal writes a xml representation of the struct instance
+func Marshal(w io.Writer, data interface{}) error {
+	return xml.NewEncoder(w).Encode(&data)
+}
+
+//Unmarshal reads a xml representation into the struct instance
+func Unmarshal(r io.Reader, result interface{}) error {
+	return xml.NewDecoder(r).Decode(&result)
+}
+
+//MarshalBytes writes a xml representation of the struct instance
+func MarshalBytes(data interface{}) ([]byte, error) {
+	return xml.Marshal(&data)
+}
+
+//UnmarshalBytes reads a xml representation into the struct instance
+func UnmarshalBytes(raw []byte, result interface{}) error {
+	return xml.Unmarshal(raw, &result)
+}
+
+//Media is a struct to helps writes and reads of a xml representation
+type Media struct {
+}
+
+//Marshal writes a xml representation of the struct instance
+func (Media) Marshal(writer io.Writer, val interface{}) error {
+	return Marshal(writer, &val)
+}
+
+//Unmarshal reads a xml representation into the struct instance
+func (Media) Unmarshal(reader io.Reader, ref interface{}) error {
+	return Unmarshal(reader, &ref)
+}
+
+//MarshalBytes writes a xml representation of the struct instance
+func (Media) MarshalBytes(val interface{}) ([]byte, error) {
+	return MarshalBytes(&val)
+}
+
+//UnmarshalBytes reads a xml representation into the struct instance
+func (Media) UnmarshalBytes(raw []byte, ref interface{}) error {
+	return UnmarshalBytes(raw, &ref)
+}
+
+func init() {
+	media.Register(ContentType, Media{})
+}
@@ -0,0 +1,69 @@
+package yaml
+
+import (
+	"io"
+	"io/ioutil"
+
+	"github.com/rjansen/haki/media"
+	yaml "gopkg.in/yaml.v2"
+)
+
+//ContentType is the media type identifier this package registers itself under
+const ContentType = "application/x-yaml"
+
+//Marshal writes a yaml representation of the struct instance
+func Marshal(w io.Writer, data interface{}) error {
+	bytes, err := yaml.Marshal(&data)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(bytes)
+	return err
+}
+
+//Unmarshal reads a yaml representation into the struct instance
+func Unmarshal(r io.Reader, result interface{}) error {
+	bytes, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	return yaml.Unmarshal(bytes, &result)
+}
+
+//MarshalBytes writes a yaml representation of the struct instance
+func MarshalBytes(data interface{}) ([]byte, error) {
+	return yaml.Marshal(&data)
+}
+
+//UnmarshalBytes reads a yaml representation into the struct instance
+func UnmarshalBytes(raw []byte, result interface{}) error {
+	return yaml.Unmarshal(raw, &result)
+}
+
+//Media is a struct to helps writes and reads of a yaml representation
+type Media struct {
+}
+
+//Marshal writes a yaml representation of the struct instance
+func (Media) Marshal(writer io.Writer, val interface{}) error {
+	return Marshal(writer, &val)
+}
+
+//Unmarshal reads a yaml representation into the struct instance
+func (Media) Unmarshal(reader io.Reader, ref interface{}) error {
+	return Unmarshal(reader, &ref)
+}
+
+//MarshalBytes writes a yaml representation of the struct instance
+func (Media) MarshalBytes(val interface{}) ([]byte, error) {
+	return MarshalBytes(&val)
+}
+
+//UnmarshalBytes reads a yaml representation into the struct instance
+func (Media) UnmarshalBytes(raw []byte, ref interface{}) error {
+	return UnmarshalBytes(raw, &ref)
+}
+
+func init() {
+	media.Register(ContentType, Media{})
+}